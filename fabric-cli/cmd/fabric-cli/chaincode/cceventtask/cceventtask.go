@@ -0,0 +1,101 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cceventtask
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/api/apifabclient"
+	cliconfig "github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/config"
+	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/printer"
+)
+
+// Task is a Task that subscribes to chaincode events and forwards them to the printer
+type Task struct {
+	id           string
+	eventHub     apifabclient.EventHub
+	ccID         string
+	eventFilter  string
+	maxEvents    int
+	timeout      time.Duration
+	cancel       chan bool
+	numReceived  int
+	registration apifabclient.ChainCodeCBE
+	callback     func(err error)
+	printer      printer.Printer
+}
+
+// New returns a new Task that registers a chaincode event listener for the given
+// ccID/eventFilter and terminates after maxEvents have been received, the timeout
+// elapses, or cancel is signaled - whichever happens first. A maxEvents of 0 means
+// the task only terminates on timeout or cancel.
+func New(id string, eventHub apifabclient.EventHub, ccID string, eventFilter string, maxEvents int,
+	timeout time.Duration, cancel chan bool, p printer.Printer, callback func(err error)) *Task {
+	return &Task{
+		id:          id,
+		eventHub:    eventHub,
+		ccID:        ccID,
+		eventFilter: eventFilter,
+		maxEvents:   maxEvents,
+		timeout:     timeout,
+		cancel:      cancel,
+		printer:     p,
+		callback:    callback,
+	}
+}
+
+// Invoke invokes the task
+func (t *Task) Invoke() {
+	// Buffered so the SDK's dispatch goroutine doesn't have to block on a send that
+	// arrives after the loop below has already returned; done unblocks a send that
+	// arrives while the buffer happens to be full at exit time.
+	eventsChan := make(chan *apifabclient.ChaincodeEvent, 1)
+	done := make(chan bool)
+	defer close(done)
+
+	registration, err := t.eventHub.RegisterChaincodeEvent(t.ccID, t.eventFilter, func(event *apifabclient.ChaincodeEvent) {
+		select {
+		case eventsChan <- event:
+		case <-done:
+		}
+	})
+	if err != nil {
+		cliconfig.Config().Logger().Errorf("(%s) - Error registering chaincode event: %s\n", t.id, err)
+		t.callback(err)
+		return
+	}
+	t.registration = registration
+	defer t.eventHub.UnregisterChaincodeEvent(t.registration)
+
+	timeoutChan := time.After(t.timeout)
+	for {
+		select {
+		case event := <-eventsChan:
+			t.numReceived++
+			cliconfig.Config().Logger().Debugf("(%s) - Received chaincode event #%d - TxID [%s], CCID [%s], EventName [%s]\n",
+				t.id, t.numReceived, event.TxID, event.ChaincodeID, event.EventName)
+			t.printer.PrintChaincodeEvent(event)
+			if t.maxEvents > 0 && t.numReceived >= t.maxEvents {
+				t.callback(nil)
+				return
+			}
+		case <-timeoutChan:
+			cliconfig.Config().Logger().Debugf("(%s) - Timed out waiting for chaincode events. Received %d of %d.\n", t.id, t.numReceived, t.maxEvents)
+			t.callback(nil)
+			return
+		case <-t.cancel:
+			cliconfig.Config().Logger().Debugf("(%s) - Cancelled. Received %d of %d chaincode events.\n", t.id, t.numReceived, t.maxEvents)
+			t.callback(nil)
+			return
+		}
+	}
+}
+
+// NumReceived returns the number of chaincode events received so far
+func (t *Task) NumReceived() int {
+	return t.numReceived
+}
@@ -14,7 +14,9 @@ import (
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/action"
 	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/chaincode/invokeerror"
+	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/chaincode/peerselection"
 	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/chaincode/responsefilter"
+	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/chaincode/retrypolicy"
 	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/chaincode/utils"
 	cliconfig "github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/config"
 	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/executor"
@@ -23,19 +25,27 @@ import (
 
 // Task is a Task that invokes a chaincode
 type Task struct {
-	executor      *executor.Executor
-	channelClient apitxn.ChannelClient
-	id            string
-	ccID          string
-	args          *action.ArgStruct
-	maxAttempts   int
-	resubmitDelay time.Duration
-	attempt       int
-	lastErr       error
-	callback      func(err error)
-	verbose       bool
-	printer       printer.Printer
-	txID          string
+	executor       *executor.Executor
+	channelClient  apitxn.ChannelClient
+	id             string
+	ccID           string
+	args           *action.ArgStruct
+	maxAttempts    int
+	resubmitDelay  time.Duration
+	retryPolicy    retrypolicy.RetryPolicy
+	attempt        int
+	lastErr        error
+	lastCode       pb.TxValidationCode
+	lastRetryDelay time.Duration
+	callback       func(err error)
+	txCallback     func(txID string, code pb.TxValidationCode, err error)
+	verbose        bool
+	printer        printer.Printer
+	txID           string
+	targets        peerselection.Selector
+	eventHub       apifabclient.EventHub
+	ledger         apitxn.ChannelLedger
+	reconcileGrace time.Duration
 }
 
 // New returns a new Task
@@ -53,10 +63,47 @@ func New(id string, channelClient apitxn.ChannelClient, ccID string, args *actio
 		callback:      callback,
 		attempt:       1,
 		resubmitDelay: resubmitDelay,
+		retryPolicy:   retrypolicy.Default(resubmitDelay),
 		verbose:       verbose,
 	}
 }
 
+// WithRetryPolicy overrides the default fixed-delay retry policy, e.g. to apply
+// exponential backoff with jitter under high-concurrency MVCC conflict storms.
+func (t *Task) WithRetryPolicy(retryPolicy retrypolicy.RetryPolicy) *Task {
+	t.retryPolicy = retryPolicy
+	return t
+}
+
+// WithTargets sets the Selector used to choose the peers that the proposal is
+// sent to. When not set, the SDK's default discovery behavior is used.
+func (t *Task) WithTargets(targets peerselection.Selector) *Task {
+	t.targets = targets
+	return t
+}
+
+// WithReconciliation enables background reconciliation of a TimeoutOnCommit: rather
+// than surfacing the timeout immediately, a background goroutine waits up to
+// gracePeriod for the tx to commit (via eventHub), falling back to a ledger
+// query to classify the outcome if no event arrives in time. See reconcile.go.
+func (t *Task) WithReconciliation(eventHub apifabclient.EventHub, ledger apitxn.ChannelLedger, gracePeriod time.Duration) *Task {
+	t.eventHub = eventHub
+	t.ledger = ledger
+	t.reconcileGrace = gracePeriod
+	return t
+}
+
+// WithTxCallback registers a callback that's invoked with the full commit outcome -
+// the TxID and TxValidationCode - for every attempt whose commit status event is
+// received, regardless of whether the task ultimately retries. Unlike callback(err),
+// this is called even when the commit fails but is going to be retried. It is not
+// called for attempts that fail before the commit wait, that time out waiting for
+// the commit event, or whose outcome is obtained through reconciliation.
+func (t *Task) WithTxCallback(txCallback func(txID string, code pb.TxValidationCode, err error)) *Task {
+	t.txCallback = txCallback
+	return t
+}
+
 // Attempts returns the number of invocation attempts that were made
 // in order to achieve a successful response
 func (t *Task) Attempts() int {
@@ -77,9 +124,9 @@ func (t *Task) Invoke() {
 			switch invokeErr.ErrorCode() {
 			case invokeerror.TransientError:
 				if t.attempt < t.maxAttempts {
-					cliconfig.Config().Logger().Debugf("(%s) - Error invoking chaincode: %s. Resubmitting ...\n", t.id, err)
+					cliconfig.Config().Logger().Debugf("(%s) - Error invoking chaincode: %s. Resubmitting in %s...\n", t.id, err, t.lastRetryDelay)
 					t.attempt++
-					if err := t.executor.SubmitDelayed(t, t.resubmitDelay); err != nil {
+					if err := t.executor.SubmitDelayed(t, t.lastRetryDelay); err != nil {
 						cliconfig.Config().Logger().Errorf("error submitting task: %s", err)
 					}
 					return
@@ -87,7 +134,10 @@ func (t *Task) Invoke() {
 				cliconfig.Config().Logger().Debugf("(%s) - Error invoking chaincode: %s. Giving up after %d attempts.\n", t.id, err, t.attempt)
 			case invokeerror.TimeoutOnCommit:
 				cliconfig.Config().Logger().Debugf("(%s) - Timeout committing Tx %s\n", t.id, t.txID)
-				// TODO: Handle somehow?
+				if t.eventHub != nil {
+					go t.reconcile()
+					return
+				}
 			}
 		}
 		t.callback(err)
@@ -101,6 +151,20 @@ func (t *Task) doInvoke() error {
 	cliconfig.Config().Logger().Debugf("(%s) - Invoking chaincode: %s, function: %s, args: %+v. Attempt #%d...\n",
 		t.id, t.ccID, t.args.Func, t.args.Args, t.attempt)
 
+	// Default to the fixed resubmit delay for transient errors that occur before a
+	// commit code is known (e.g. target selection, proposal send); the commit-status
+	// branch below overrides this with the retry policy's delay for that code.
+	t.lastRetryDelay = t.resubmitDelay
+
+	var targets []apifabclient.Peer
+	if t.targets != nil {
+		peers, err := t.targets.Select()
+		if err != nil {
+			return invokeerror.Errorf(invokeerror.TransientError, "error selecting target peers: %v", err)
+		}
+		targets = peers
+	}
+
 	txStatusEvents := make(chan apitxn.ExecuteTxResponse)
 	txnID, err := t.channelClient.ExecuteTxWithOpts(
 		apitxn.ExecuteTxRequest{
@@ -109,10 +173,10 @@ func (t *Task) doInvoke() error {
 			Args:        utils.AsBytes(t.args.Args),
 		},
 		apitxn.ExecuteTxOpts{
-			TxFilter: responsefilter.New(t.verbose, t.printer),
-			Notifier: txStatusEvents,
-			// ProposalProcessors: asProposalProcessors(t.targets),
-			Timeout: cliconfig.Config().Timeout(),
+			TxFilter:           responsefilter.New(t.verbose, t.printer),
+			Notifier:           txStatusEvents,
+			ProposalProcessors: asProposalProcessors(targets),
+			Timeout:            cliconfig.Config().Timeout(),
 		},
 	)
 	if err != nil {
@@ -125,14 +189,20 @@ func (t *Task) doInvoke() error {
 
 	select {
 	case s := <-txStatusEvents:
+		t.lastCode = s.TxValidationCode
+		if t.txCallback != nil {
+			t.txCallback(txnID.ID, s.TxValidationCode, s.Error)
+		}
 		switch s.TxValidationCode {
 		case pb.TxValidationCode_VALID:
 			cliconfig.Config().Logger().Debugf("(%s) - Successfully committed transaction [%s] ...\n", t.id, txnID.ID)
 			return nil
-		case pb.TxValidationCode_DUPLICATE_TXID, pb.TxValidationCode_MVCC_READ_CONFLICT, pb.TxValidationCode_PHANTOM_READ_CONFLICT:
-			cliconfig.Config().Logger().Debugf("(%s) - Transaction commit failed for [%s] with code [%s]. This is most likely a transient error.\n", t.id, txnID.ID, s.TxValidationCode)
-			return invokeerror.Wrapf(invokeerror.TransientError, s.Error, "invoke Error received from eventhub for TxID [%s]. Code: %s", txnID.ID, s.TxValidationCode)
 		default:
+			if shouldRetry, delay := t.retryPolicy.ShouldRetry(s.TxValidationCode, t.attempt); shouldRetry {
+				t.lastRetryDelay = delay
+				cliconfig.Config().Logger().Debugf("(%s) - Transaction commit failed for [%s] with code [%s]. This is most likely a transient error.\n", t.id, txnID.ID, s.TxValidationCode)
+				return invokeerror.Wrapf(invokeerror.TransientError, s.Error, "invoke Error received from eventhub for TxID [%s]. Code: %s", txnID.ID, s.TxValidationCode)
+			}
 			cliconfig.Config().Logger().Debugf("(%s) - Transaction commit failed for [%s] with code [%s].\n", t.id, txnID.ID, s.TxValidationCode)
 			return invokeerror.Wrapf(invokeerror.PersistentError, s.Error, "invoke Error received from eventhub for TxID [%s]. Code: %s", txnID.ID, s.TxValidationCode)
 		}
@@ -0,0 +1,83 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoketask
+
+import (
+	"time"
+
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	cliconfig "github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/config"
+)
+
+// reconcile runs in its own goroutine after a TimeoutOnCommit: rather than
+// silently dropping a transaction that may still commit, it waits up to
+// t.reconcileGrace for a commit event from the event hub, and falls back to a
+// ledger query to classify the outcome if no event arrives in time.
+func (t *Task) reconcile() {
+	cliconfig.Config().Logger().Debugf("(%s) - Reconciling timed-out Tx [%s] ...\n", t.id, t.txID)
+
+	codeChan := make(chan pb.TxValidationCode, 1)
+	registration, err := t.eventHub.RegisterTxEvent(t.txID, func(txID string, code pb.TxValidationCode, err error) {
+		codeChan <- code
+	})
+	if err != nil {
+		cliconfig.Config().Logger().Errorf("(%s) - error registering for Tx event during reconciliation: %s\n", t.id, err)
+		t.reconcileFromLedger()
+		return
+	}
+	defer t.eventHub.UnregisterTxEvent(registration)
+
+	select {
+	case code := <-codeChan:
+		t.finishReconciliation(code)
+	case <-time.After(t.reconcileGrace):
+		cliconfig.Config().Logger().Debugf("(%s) - Grace period elapsed for Tx [%s] without a commit event. Querying ledger ...\n", t.id, t.txID)
+		t.reconcileFromLedger()
+	}
+}
+
+// reconcileFromLedger classifies a timed-out Tx as actually-committed, orphaned
+// (definitively not committed), or unknown (the ledger couldn't be consulted).
+func (t *Task) reconcileFromLedger() {
+	if t.ledger == nil {
+		cliconfig.Config().Logger().Debugf("(%s) - No ledger client configured for reconciliation of Tx [%s]. Outcome is unknown.\n", t.id, t.txID)
+		t.callback(t.lastErr)
+		return
+	}
+
+	processedTx, err := t.ledger.QueryTransaction(t.txID, nil)
+	if err != nil {
+		cliconfig.Config().Logger().Debugf("(%s) - Unable to determine outcome of Tx [%s]: %s. Outcome is unknown.\n", t.id, t.txID, err)
+		t.callback(t.lastErr)
+		return
+	}
+
+	t.finishReconciliation(pb.TxValidationCode(processedTx.ValidationCode))
+}
+
+// finishReconciliation applies the retry policy to a (possibly delayed) commit
+// outcome that was obtained outside of the normal doInvoke commit wait.
+func (t *Task) finishReconciliation(code pb.TxValidationCode) {
+	if code == pb.TxValidationCode_VALID {
+		cliconfig.Config().Logger().Debugf("(%s) - Tx [%s] eventually committed successfully. Suppressing the timeout error.\n", t.id, t.txID)
+		t.callback(nil)
+		return
+	}
+
+	if shouldRetry, delay := t.retryPolicy.ShouldRetry(code, t.attempt); shouldRetry && t.attempt < t.maxAttempts {
+		cliconfig.Config().Logger().Debugf("(%s) - Tx [%s] did not commit (code [%s]). Resubmitting in %s...\n", t.id, t.txID, code, delay)
+		t.attempt++
+		if err := t.executor.SubmitDelayed(t, delay); err != nil {
+			cliconfig.Config().Logger().Errorf("(%s) - error submitting task: %s\n", t.id, err)
+			t.callback(t.lastErr)
+		}
+		return
+	}
+
+	cliconfig.Config().Logger().Debugf("(%s) - Tx [%s] was orphaned or its outcome is unknown (code [%s]). Giving up.\n", t.id, t.txID, code)
+	t.callback(t.lastErr)
+}
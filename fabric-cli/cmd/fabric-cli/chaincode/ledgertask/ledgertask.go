@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledgertask
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/api/apifabclient"
+	"github.com/hyperledger/fabric-sdk-go/api/apitxn"
+	cliconfig "github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/config"
+	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/printer"
+)
+
+// Mode specifies which ChannelLedger query the Task performs
+type Mode int
+
+const (
+	// Info queries the blockchain info for the channel
+	Info Mode = iota
+	// BlockByNumber queries a block by its number
+	BlockByNumber
+	// BlockByHash queries a block by its hash
+	BlockByHash
+	// Transaction queries a transaction by its ID
+	Transaction
+	// Config queries the channel configuration block
+	Config
+)
+
+// Task is a Task that queries the channel ledger
+type Task struct {
+	id          string
+	ledger      apitxn.ChannelLedger
+	mode        Mode
+	blockNumber int
+	blockHash   []byte
+	txID        string
+	targets     []apifabclient.Peer
+	callback    func(err error)
+	printer     printer.Printer
+}
+
+// New returns a new Task that queries the given ChannelLedger according to mode.
+// The optional targets restrict the query to a specific set of peers; when empty
+// the ledger client falls back to its default peer selection.
+func New(id string, ledger apitxn.ChannelLedger, mode Mode, targets []apifabclient.Peer, p printer.Printer, callback func(err error)) *Task {
+	return &Task{
+		id:       id,
+		ledger:   ledger,
+		mode:     mode,
+		targets:  targets,
+		printer:  p,
+		callback: callback,
+	}
+}
+
+// WithBlockNumber sets the block number for a BlockByNumber query
+func (t *Task) WithBlockNumber(blockNumber int) *Task {
+	t.blockNumber = blockNumber
+	return t
+}
+
+// WithBlockHash sets the block hash for a BlockByHash query
+func (t *Task) WithBlockHash(blockHash []byte) *Task {
+	t.blockHash = blockHash
+	return t
+}
+
+// WithTxID sets the transaction ID for a Transaction query
+func (t *Task) WithTxID(txID string) *Task {
+	t.txID = txID
+	return t
+}
+
+// Invoke invokes the task
+func (t *Task) Invoke() {
+	if err := t.doQuery(); err != nil {
+		cliconfig.Config().Logger().Debugf("(%s) - Error querying ledger: %s\n", t.id, err)
+		t.callback(err)
+		return
+	}
+	cliconfig.Config().Logger().Debugf("(%s) - Ledger query was successful\n", t.id)
+	t.callback(nil)
+}
+
+func (t *Task) doQuery() error {
+	targets := asProposalProcessors(t.targets)
+	switch t.mode {
+	case Info:
+		info, err := t.ledger.QueryInfo(targets)
+		if err != nil {
+			return err
+		}
+		t.printer.PrintBlockchainInfo(info)
+	case BlockByNumber:
+		block, err := t.ledger.QueryBlock(t.blockNumber, targets)
+		if err != nil {
+			return err
+		}
+		t.printer.PrintBlock(block)
+	case BlockByHash:
+		block, err := t.ledger.QueryBlockByHash(t.blockHash, targets)
+		if err != nil {
+			return err
+		}
+		t.printer.PrintBlock(block)
+	case Transaction:
+		tx, err := t.ledger.QueryTransaction(t.txID, targets)
+		if err != nil {
+			return err
+		}
+		t.printer.PrintProcessedTransaction(tx)
+	case Config:
+		cfg, err := t.ledger.QueryConfig(targets)
+		if err != nil {
+			return err
+		}
+		t.printer.PrintChannelConfig(cfg)
+	}
+	return nil
+}
+
+func asProposalProcessors(peers []apifabclient.Peer) []apitxn.ProposalProcessor {
+	targets := make([]apitxn.ProposalProcessor, len(peers))
+	for i, p := range peers {
+		targets[i] = p
+	}
+	return targets
+}
@@ -0,0 +1,180 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package loadtask drives a configurable number of invoke or query tasks through
+// the executor at a bounded rate and concurrency, collecting latency and outcome
+// statistics for the run.
+package loadtask
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/api/apitxn"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/action"
+	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/chaincode/invoketask"
+	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/chaincode/peerselection"
+	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/chaincode/querytask"
+	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/chaincode/retrypolicy"
+	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/executor"
+	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/printer"
+)
+
+// Mode specifies the type of sub-task that the load generator drives
+type Mode int
+
+const (
+	// Invoke drives a chaincode invocation for each request
+	Invoke Mode = iota
+	// Query drives a chaincode query for each request
+	Query
+)
+
+// ArgGenerator produces the arguments for the i'th (0-based) request
+type ArgGenerator func(iteration int) *action.ArgStruct
+
+// NewKeyArgGenerator returns an ArgGenerator that reproduces Fabric's "new key
+// per invoke" concurrency scenarios: keyFormat (e.g. "key-%d") is formatted with
+// a monotonically increasing counter and prepended to the fixed args.
+func NewKeyArgGenerator(fn string, keyFormat string, args []string) ArgGenerator {
+	return func(iteration int) *action.ArgStruct {
+		key := fmt.Sprintf(keyFormat, iteration)
+		return &action.ArgStruct{
+			Func: fn,
+			Args: append([]string{key}, args...),
+		}
+	}
+}
+
+// Task drives N invoke or query tasks through the executor
+type Task struct {
+	id            string
+	executor      *executor.Executor
+	channelClient apitxn.ChannelClient
+	mode          Mode
+	ccID          string
+	argGen        ArgGenerator
+	n             int
+	rps           float64
+	concurrency   int
+	maxAttempts   int
+	resubmitDelay time.Duration
+	retryPolicy   retrypolicy.RetryPolicy
+	targets       peerselection.Selector
+	verbose       bool
+	printer       printer.Printer
+	callback      func(err error)
+	stats         *Stats
+}
+
+// New returns a new load-generator Task. rps of 0 disables the rate limit;
+// concurrency bounds the number of requests in flight at any one time.
+func New(id string, executor *executor.Executor, channelClient apitxn.ChannelClient, mode Mode, ccID string,
+	argGen ArgGenerator, n int, rps float64, concurrency int, maxAttempts int, resubmitDelay time.Duration,
+	verbose bool, p printer.Printer, callback func(err error)) *Task {
+	return &Task{
+		id:            id,
+		executor:      executor,
+		channelClient: channelClient,
+		mode:          mode,
+		ccID:          ccID,
+		argGen:        argGen,
+		n:             n,
+		rps:           rps,
+		concurrency:   concurrency,
+		maxAttempts:   maxAttempts,
+		resubmitDelay: resubmitDelay,
+		verbose:       verbose,
+		printer:       p,
+		callback:      callback,
+		stats:         newStats(),
+	}
+}
+
+// WithRetryPolicy overrides the retry policy used by the underlying invoke tasks
+func (t *Task) WithRetryPolicy(retryPolicy retrypolicy.RetryPolicy) *Task {
+	t.retryPolicy = retryPolicy
+	return t
+}
+
+// WithTargets sets the Selector used to choose the peers that requests are sent to
+func (t *Task) WithTargets(targets peerselection.Selector) *Task {
+	t.targets = targets
+	return t
+}
+
+// Stats returns the latency/outcome statistics collected for the run
+func (t *Task) Stats() *Stats {
+	return t.stats
+}
+
+// Invoke drives the N requests to completion and prints a summary of the latency
+// (min, mean, p50, p90, p99, max) and commit-code counts; no per-bucket latency
+// histogram is printed.
+func (t *Task) Invoke() {
+	var limiter *tokenBucket
+	if t.rps > 0 {
+		limiter = newTokenBucket(t.rps)
+		defer limiter.Stop()
+	}
+
+	sem := make(chan struct{}, t.concurrency)
+	var wg sync.WaitGroup
+
+	for i := 0; i < t.n; i++ {
+		if limiter != nil {
+			limiter.Take()
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+
+		iteration := i
+		start := time.Now()
+		onDone := func(attempts int, err error) {
+			t.stats.record(time.Since(start), attempts, err)
+			<-sem
+			wg.Done()
+		}
+
+		// Dispatched as a plain goroutine rather than via t.executor.Submit: Invoke
+		// itself may be running as an executor task, and waiting here for children
+		// submitted to that same (possibly bounded) executor would deadlock it.
+		// Concurrency is already bounded by sem/limiter above; the executor is still
+		// used by the sub-tasks themselves for resubmitting on a transient retry.
+		go t.newSubTask(iteration, onDone).Invoke()
+	}
+
+	wg.Wait()
+
+	t.stats.Summary().Print()
+	t.callback(nil)
+}
+
+func (t *Task) newSubTask(iteration int, onDone func(attempts int, err error)) executor.Task {
+	args := t.argGen(iteration)
+	id := fmt.Sprintf("%s-%d", t.id, iteration)
+
+	switch t.mode {
+	case Invoke:
+		var invTask *invoketask.Task
+		invTask = invoketask.New(id, t.channelClient, t.ccID, args, t.executor, t.maxAttempts, t.resubmitDelay, t.verbose, t.printer,
+			func(err error) { onDone(invTask.Attempts(), err) })
+		if t.retryPolicy != nil {
+			invTask.WithRetryPolicy(t.retryPolicy)
+		}
+		if t.targets != nil {
+			invTask.WithTargets(t.targets)
+		}
+		invTask.WithTxCallback(func(txID string, code pb.TxValidationCode, err error) {
+			t.stats.recordCode(code)
+		})
+		return invTask
+	default:
+		return querytask.New(id, t.channelClient, args, t.printer, t.verbose, func(err error) { onDone(1, err) }).WithTargets(t.targets)
+	}
+}
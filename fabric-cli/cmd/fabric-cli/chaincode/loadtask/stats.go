@@ -0,0 +1,126 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package loadtask
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+// Stats accumulates per-request latency, attempt counts, and commit-code
+// breakdown for a load run.
+type Stats struct {
+	mutex      sync.Mutex
+	latencies  []time.Duration
+	attempts   []int
+	errors     int
+	codeCounts map[pb.TxValidationCode]int
+}
+
+func newStats() *Stats {
+	return &Stats{
+		codeCounts: make(map[pb.TxValidationCode]int),
+	}
+}
+
+func (s *Stats) record(latency time.Duration, attempts int, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.latencies = append(s.latencies, latency)
+	s.attempts = append(s.attempts, attempts)
+	if err != nil {
+		s.errors++
+	}
+}
+
+func (s *Stats) recordCode(code pb.TxValidationCode) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.codeCounts[code]++
+}
+
+// Summary is a snapshot of the statistics collected for a load run
+type Summary struct {
+	Count         int
+	Errors        int
+	TotalAttempts int
+	CodeCounts    map[pb.TxValidationCode]int
+	MinLatency    time.Duration
+	MaxLatency    time.Duration
+	MeanLatency   time.Duration
+	P50Latency    time.Duration
+	P90Latency    time.Duration
+	P99Latency    time.Duration
+}
+
+// Summary computes percentile latencies over the requests recorded so far
+func (s *Stats) Summary() Summary {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sorted := make([]time.Duration, len(s.latencies))
+	copy(sorted, s.latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	codeCounts := make(map[pb.TxValidationCode]int, len(s.codeCounts))
+	for code, count := range s.codeCounts {
+		codeCounts[code] = count
+	}
+
+	summary := Summary{
+		Count:      len(sorted),
+		Errors:     s.errors,
+		CodeCounts: codeCounts,
+	}
+	for _, a := range s.attempts {
+		summary.TotalAttempts += a
+	}
+	if len(sorted) == 0 {
+		return summary
+	}
+
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+	summary.MinLatency = sorted[0]
+	summary.MaxLatency = sorted[len(sorted)-1]
+	summary.MeanLatency = total / time.Duration(len(sorted))
+	summary.P50Latency = percentile(sorted, 0.5)
+	summary.P90Latency = percentile(sorted, 0.9)
+	summary.P99Latency = percentile(sorted, 0.99)
+
+	return summary
+}
+
+// Print writes a human-readable rendering of the summary to stdout. Summary is
+// defined in this package (loadtask), not printer, so that loadtask can depend
+// on printer for its sub-tasks without printer needing to depend back on loadtask.
+func (s Summary) Print() {
+	fmt.Printf("\n--- Load results ---\n")
+	fmt.Printf("Requests: %d, Errors: %d, Total attempts: %d\n", s.Count, s.Errors, s.TotalAttempts)
+	fmt.Printf("Latency - min: %s, mean: %s, p50: %s, p90: %s, p99: %s, max: %s\n",
+		s.MinLatency, s.MeanLatency, s.P50Latency, s.P90Latency, s.P99Latency, s.MaxLatency)
+	for code, count := range s.CodeCounts {
+		fmt.Printf("  %s: %d\n", code, count)
+	}
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p * float64(len(sorted)))
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
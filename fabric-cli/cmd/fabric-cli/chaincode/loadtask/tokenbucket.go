@@ -0,0 +1,33 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package loadtask
+
+import "time"
+
+// tokenBucket is a simple blocking rate limiter: Take() blocks until a token
+// is available, admitting requests at a steady rate of ratePerSec.
+type tokenBucket struct {
+	ticker *time.Ticker
+}
+
+func newTokenBucket(ratePerSec float64) *tokenBucket {
+	interval := time.Duration(float64(time.Second) / ratePerSec)
+	if interval <= 0 {
+		interval = time.Nanosecond
+	}
+	return &tokenBucket{ticker: time.NewTicker(interval)}
+}
+
+// Take blocks until the next token is available
+func (b *tokenBucket) Take() {
+	<-b.ticker.C
+}
+
+// Stop releases the underlying ticker
+func (b *tokenBucket) Stop() {
+	b.ticker.Stop()
+}
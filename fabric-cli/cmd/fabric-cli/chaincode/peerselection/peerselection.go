@@ -0,0 +1,90 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package peerselection provides strategies for choosing the set of peers that a
+// proposal is sent to, in place of the SDK's default discovery behavior.
+package peerselection
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/api/apifabclient"
+)
+
+// Selector resolves the set of peers that a proposal should be sent to
+type Selector interface {
+	Select() ([]apifabclient.Peer, error)
+}
+
+// NewURLSelector returns a Selector that targets the explicit set of peer URLs,
+// resolved against the peers known to the channel.
+func NewURLSelector(channel apifabclient.Channel, urls []string) Selector {
+	return &urlSelector{channel: channel, urls: urls}
+}
+
+type urlSelector struct {
+	channel apifabclient.Channel
+	urls    []string
+}
+
+func (s *urlSelector) Select() ([]apifabclient.Peer, error) {
+	peers, err := s.channel.Peers()
+	if err != nil {
+		return nil, err
+	}
+	byURL := make(map[string]apifabclient.Peer, len(peers))
+	for _, p := range peers {
+		byURL[p.URL()] = p
+	}
+	targets := make([]apifabclient.Peer, 0, len(s.urls))
+	for _, url := range s.urls {
+		p, ok := byURL[url]
+		if !ok {
+			return nil, fmt.Errorf("peer [%s] is not known to channel [%s]", url, s.channel.Name())
+		}
+		targets = append(targets, p)
+	}
+	return targets, nil
+}
+
+// NewPrimaryPeerSelector returns a Selector that targets the channel's configured
+// primary peer.
+func NewPrimaryPeerSelector(channel apifabclient.Channel) Selector {
+	return &primaryPeerSelector{channel: channel}
+}
+
+type primaryPeerSelector struct {
+	channel apifabclient.Channel
+}
+
+func (s *primaryPeerSelector) Select() ([]apifabclient.Peer, error) {
+	primaryPeer := s.channel.PrimaryPeer()
+	if primaryPeer == nil {
+		return nil, fmt.Errorf("no primary peer configured for channel [%s]", s.channel.Name())
+	}
+	return []apifabclient.Peer{primaryPeer}, nil
+}
+
+// NewEndorsingPeerSelector returns a Selector that dynamically picks the set of
+// peers that satisfy ccID's endorsement policy, via the SDK's selection service.
+// The candidate set the policy is evaluated against is the channel's peers.
+func NewEndorsingPeerSelector(channel apifabclient.Channel, selectionService apifabclient.SelectionService, ccID string) Selector {
+	return &endorsingPeerSelector{channel: channel, selectionService: selectionService, ccID: ccID}
+}
+
+type endorsingPeerSelector struct {
+	channel          apifabclient.Channel
+	selectionService apifabclient.SelectionService
+	ccID             string
+}
+
+func (s *endorsingPeerSelector) Select() ([]apifabclient.Peer, error) {
+	candidates, err := s.channel.Peers()
+	if err != nil {
+		return nil, err
+	}
+	return s.selectionService.GetEndorsersForChaincode(candidates, s.ccID)
+}
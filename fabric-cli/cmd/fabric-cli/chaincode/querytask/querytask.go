@@ -7,8 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package querytask
 
 import (
+	"github.com/hyperledger/fabric-sdk-go/api/apifabclient"
 	"github.com/hyperledger/fabric-sdk-go/api/apitxn"
 	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/action"
+	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/chaincode/peerselection"
 	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/chaincode/responsefilter"
 	"github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/chaincode/utils"
 	cliconfig "github.com/securekey/fabric-examples/fabric-cli/cmd/fabric-cli/config"
@@ -23,6 +25,7 @@ type Task struct {
 	callback      func(err error)
 	printer       printer.Printer
 	verbose       bool
+	targets       peerselection.Selector
 }
 
 // New creates a new query Task
@@ -37,8 +40,26 @@ func New(id string, channelClient apitxn.ChannelClient, args *action.ArgStruct,
 	}
 }
 
+// WithTargets sets the Selector used to choose the peers that the query is sent
+// to. When not set, the SDK's default discovery behavior is used.
+func (t *Task) WithTargets(targets peerselection.Selector) *Task {
+	t.targets = targets
+	return t
+}
+
 // Invoke invokes the query task
 func (t *Task) Invoke() {
+	var targets []apifabclient.Peer
+	if t.targets != nil {
+		peers, err := t.targets.Select()
+		if err != nil {
+			cliconfig.Config().Logger().Debugf("(%s) - Error selecting target peers: %s\n", t.id, err)
+			t.callback(err)
+			return
+		}
+		targets = peers
+	}
+
 	if _, err := t.channelClient.QueryWithOpts(
 		apitxn.QueryRequest{
 			ChaincodeID: cliconfig.Config().ChaincodeID(),
@@ -46,8 +67,9 @@ func (t *Task) Invoke() {
 			Args:        utils.AsBytes(t.args.Args),
 		},
 		apitxn.QueryOpts{
-			TxFilter: responsefilter.New(t.verbose, t.printer),
-			Timeout:  cliconfig.Config().Timeout(),
+			TxFilter:           responsefilter.New(t.verbose, t.printer),
+			ProposalProcessors: asProposalProcessors(targets),
+			Timeout:            cliconfig.Config().Timeout(),
 		},
 	); err != nil {
 		cliconfig.Config().Logger().Debugf("(%s) - Error querying chaincode: %s\n", t.id, err)
@@ -57,3 +79,11 @@ func (t *Task) Invoke() {
 		t.callback(nil)
 	}
 }
+
+func asProposalProcessors(peers []apifabclient.Peer) []apitxn.ProposalProcessor {
+	targets := make([]apitxn.ProposalProcessor, len(peers))
+	for i, p := range peers {
+		targets[i] = p
+	}
+	return targets
+}
@@ -0,0 +1,84 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package retrypolicy
+
+import (
+	"math/rand"
+	"time"
+
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+// RetryPolicy determines whether an invocation that failed with the given
+// TxValidationCode should be resubmitted, and how long to wait before doing so.
+// attempt is the 1-based number of the attempt that just failed.
+type RetryPolicy interface {
+	ShouldRetry(code pb.TxValidationCode, attempt int) (bool, time.Duration)
+}
+
+// Default returns the RetryPolicy used when none is provided: a fixed delay for
+// the set of codes that are typically transient under concurrent load.
+func Default(delay time.Duration) RetryPolicy {
+	return &fixedDelayPolicy{
+		delay: delay,
+		codes: map[pb.TxValidationCode]bool{
+			pb.TxValidationCode_DUPLICATE_TXID:        true,
+			pb.TxValidationCode_MVCC_READ_CONFLICT:    true,
+			pb.TxValidationCode_PHANTOM_READ_CONFLICT: true,
+		},
+	}
+}
+
+type fixedDelayPolicy struct {
+	delay time.Duration
+	codes map[pb.TxValidationCode]bool
+}
+
+func (p *fixedDelayPolicy) ShouldRetry(code pb.TxValidationCode, attempt int) (bool, time.Duration) {
+	return p.codes[code], p.delay
+}
+
+// ExponentialBackoff returns a RetryPolicy that retries the given set of codes with
+// an exponentially increasing delay, capped at maxDelay, with up to +/-jitterFraction
+// of random jitter added to avoid a thundering herd against the peer.
+func ExponentialBackoff(codes []pb.TxValidationCode, baseDelay, maxDelay time.Duration, jitterFraction float64) RetryPolicy {
+	codeSet := make(map[pb.TxValidationCode]bool, len(codes))
+	for _, c := range codes {
+		codeSet[c] = true
+	}
+	return &exponentialBackoffPolicy{
+		codes:          codeSet,
+		baseDelay:      baseDelay,
+		maxDelay:       maxDelay,
+		jitterFraction: jitterFraction,
+	}
+}
+
+type exponentialBackoffPolicy struct {
+	codes          map[pb.TxValidationCode]bool
+	baseDelay      time.Duration
+	maxDelay       time.Duration
+	jitterFraction float64
+}
+
+func (p *exponentialBackoffPolicy) ShouldRetry(code pb.TxValidationCode, attempt int) (bool, time.Duration) {
+	if !p.codes[code] {
+		return false, 0
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := p.baseDelay << uint(attempt-1)
+	if delay <= 0 || delay > p.maxDelay {
+		delay = p.maxDelay
+	}
+	if p.jitterFraction > 0 {
+		jitter := float64(delay) * p.jitterFraction
+		delay = delay - time.Duration(jitter) + time.Duration(rand.Float64()*2*jitter)
+	}
+	return true, delay
+}